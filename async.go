@@ -0,0 +1,236 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// OverflowPolicy controls what an AsyncWriter does when its queue is
+// full and a new message needs to be enqueued.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest queued message to make room for
+	// the new one.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the message being enqueued, leaving the queue
+	// untouched.
+	DropNewest
+	// Block waits for room in the queue, applying backpressure to the
+	// caller instead of dropping anything.
+	Block
+)
+
+// asyncMsg is one queued log call, captured so it can be replayed by the
+// background goroutine exactly as writeAndRetry expects it.
+type asyncMsg struct {
+	priority rfc5424.Priority
+	msg      string
+	sd       []rfc5424.StructuredData
+}
+
+// Stats reports AsyncWriter queue health.
+type Stats struct {
+	Queued  int
+	Dropped uint64
+}
+
+// AsyncWriter wraps a Writer so that logging never blocks the caller on
+// syslog I/O. Messages are enqueued onto a buffered channel and written
+// by a background goroutine; when the queue is full, Policy decides
+// whether to drop the oldest queued message, drop the new one, or
+// block.
+type AsyncWriter struct {
+	w      *Writer
+	policy OverflowPolicy
+	queue  chan asyncMsg
+
+	dropped uint64
+	pending sync.WaitGroup // one per message queued or in flight; used by Flush
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup // background loop goroutine
+}
+
+// NewAsyncWriter starts a background goroutine that drains queued
+// messages into w. queueSize bounds how many messages may be buffered
+// before policy kicks in.
+func NewAsyncWriter(w *Writer, queueSize int, policy OverflowPolicy) *AsyncWriter {
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	a := &AsyncWriter{
+		w:      w,
+		policy: policy,
+		queue:  make(chan asyncMsg, queueSize),
+		done:   make(chan struct{}),
+	}
+	a.wg.Add(1)
+	go a.loop()
+	return a
+}
+
+func (a *AsyncWriter) loop() {
+	defer a.wg.Done()
+	for {
+		select {
+		case m := <-a.queue:
+			a.writeOne(m)
+		case <-a.done:
+			a.drain()
+			return
+		}
+	}
+}
+
+func (a *AsyncWriter) writeOne(m asyncMsg) {
+	defer a.pending.Done()
+	a.w.writeAndRetry(m.priority, m.msg, m.sd...)
+}
+
+// drain writes out whatever is left in the queue without blocking on
+// new messages, so Close doesn't silently discard work in flight.
+func (a *AsyncWriter) drain() {
+	for {
+		select {
+		case m := <-a.queue:
+			a.writeOne(m)
+		default:
+			return
+		}
+	}
+}
+
+// enqueue applies policy and queues m. Every path that accepts m calls
+// a.pending.Add(1); every path that drops a message (outright, or by
+// evicting one already queued) balances it with a.pending.Done(), so
+// Flush's a.pending.Wait() covers exactly the messages still owed a
+// write attempt.
+func (a *AsyncWriter) enqueue(m asyncMsg) error {
+	select {
+	case <-a.done:
+		return errors.New("goggler: async writer is closed")
+	default:
+	}
+	switch a.policy {
+	case Block:
+		a.pending.Add(1)
+		select {
+		case a.queue <- m:
+			return nil
+		case <-a.done:
+			a.pending.Done()
+			return errors.New("goggler: async writer is closed")
+		}
+	case DropNewest:
+		a.pending.Add(1)
+		select {
+		case a.queue <- m:
+			return nil
+		default:
+			a.pending.Done()
+			atomic.AddUint64(&a.dropped, 1)
+			return nil
+		}
+	default: // DropOldest
+		a.pending.Add(1)
+		select {
+		case a.queue <- m:
+			return nil
+		default:
+		}
+		select {
+		case <-a.queue:
+			atomic.AddUint64(&a.dropped, 1)
+			a.pending.Done() // the evicted message will never be written
+		default:
+		}
+		select {
+		case a.queue <- m:
+		default:
+			atomic.AddUint64(&a.dropped, 1)
+			a.pending.Done()
+		}
+		return nil
+	}
+}
+
+// Stats reports how many messages are currently queued and how many
+// have been dropped since the AsyncWriter was created.
+func (a *AsyncWriter) Stats() Stats {
+	return Stats{Queued: len(a.queue), Dropped: atomic.LoadUint64(&a.dropped)}
+}
+
+// Flush blocks until every message queued so far has been written (or
+// dropped), or ctx is done, whichever comes first. Unlike polling the
+// queue length, this also waits for a message the background goroutine
+// has already dequeued but not yet finished writing.
+func (a *AsyncWriter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new messages, drains whatever is already
+// queued, and closes the underlying Writer.
+func (a *AsyncWriter) Close() error {
+	a.closeOnce.Do(func() { close(a.done) })
+	a.wg.Wait()
+	return a.w.Close()
+}
+
+// Emerg queues a message with severity LOG_EMERG.
+func (a *AsyncWriter) Emerg(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Emergency, msg: m})
+}
+
+// Alert queues a message with severity LOG_ALERT.
+func (a *AsyncWriter) Alert(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Alert, msg: m})
+}
+
+// Crit queues a message with severity LOG_CRIT.
+func (a *AsyncWriter) Crit(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Crit, msg: m})
+}
+
+// Err queues a message with severity LOG_ERR.
+func (a *AsyncWriter) Err(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Error, msg: m})
+}
+
+// Warning queues a message with severity LOG_WARNING.
+func (a *AsyncWriter) Warning(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Warning, msg: m})
+}
+
+// Notice queues a message with severity LOG_NOTICE.
+func (a *AsyncWriter) Notice(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Notice, msg: m})
+}
+
+// Info queues a message with severity LOG_INFO.
+func (a *AsyncWriter) Info(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Info, msg: m})
+}
+
+// Debug queues a message with severity LOG_DEBUG.
+func (a *AsyncWriter) Debug(m string) error {
+	return a.enqueue(asyncMsg{priority: rfc5424.Debug, msg: m})
+}
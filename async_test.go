@@ -0,0 +1,139 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// delayedConn is a serverConn whose writeMessage takes delay to
+// complete, for exercising AsyncWriter timing.
+type delayedConn struct {
+	delay time.Duration
+}
+
+func (c *delayedConn) writeMessage(*rfc5424.Message) (int, error) {
+	time.Sleep(c.delay)
+	return 0, nil
+}
+func (c *delayedConn) setWriteDeadline(time.Time) error { return nil }
+func (c *delayedConn) close() error                     { return nil }
+
+func newTestAsyncWriter(delay time.Duration, queueSize int, policy OverflowPolicy) *AsyncWriter {
+	w := &Writer{
+		priority: rfc5424.Info,
+		hostname: "host",
+		appname:  "app",
+		opts:     defaultWriterOptions(),
+		conn:     &delayedConn{delay: delay},
+	}
+	return NewAsyncWriter(w, queueSize, policy)
+}
+
+// TestAsyncWriterFlushWaitsForInFlightWrite reproduces the scenario
+// where the background loop has already dequeued a message (so the
+// queue is empty) but is still inside a slow writeAndRetry call: Flush
+// must not return until that write completes.
+func TestAsyncWriterFlushWaitsForInFlightWrite(t *testing.T) {
+	a := newTestAsyncWriter(150*time.Millisecond, 4, DropNewest)
+	defer a.Close()
+
+	if err := a.Emerg("slow"); err != nil {
+		t.Fatalf("Emerg: %v", err)
+	}
+	// give the loop goroutine time to dequeue and start the slow write
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := a.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("Flush returned after %s, want it to wait out the in-flight ~150ms write", elapsed)
+	}
+}
+
+// TestAsyncWriterFlushRespectsContext checks Flush gives up when ctx
+// expires before the queue drains.
+func TestAsyncWriterFlushRespectsContext(t *testing.T) {
+	a := newTestAsyncWriter(200*time.Millisecond, 4, DropNewest)
+	defer a.Close()
+
+	if err := a.Info("slow"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Flush(ctx); err == nil {
+		t.Fatal("Flush returned nil, want context deadline exceeded")
+	}
+}
+
+func mustEnqueue(t *testing.T, a *AsyncWriter, msg string) {
+	t.Helper()
+	if err := a.Info(msg); err != nil {
+		t.Fatalf("enqueue %q: %v", msg, err)
+	}
+}
+
+func TestAsyncWriterDropNewestOverflow(t *testing.T) {
+	a := newTestAsyncWriter(100*time.Millisecond, 1, DropNewest)
+	defer a.Close()
+
+	mustEnqueue(t, a, "a") // picked up by the loop almost immediately
+	time.Sleep(20 * time.Millisecond)
+	mustEnqueue(t, a, "b") // fills the single-slot queue
+	mustEnqueue(t, a, "c") // queue full: dropped
+
+	if got := a.Stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestAsyncWriterDropOldestOverflow(t *testing.T) {
+	a := newTestAsyncWriter(100*time.Millisecond, 1, DropOldest)
+	defer a.Close()
+
+	mustEnqueue(t, a, "a")
+	time.Sleep(20 * time.Millisecond)
+	mustEnqueue(t, a, "b")
+	mustEnqueue(t, a, "c") // evicts "b" to make room
+
+	if got := a.Stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestAsyncWriterBlockWaitsForRoom(t *testing.T) {
+	a := newTestAsyncWriter(50*time.Millisecond, 1, Block)
+	defer a.Close()
+
+	mustEnqueue(t, a, "a")
+	time.Sleep(10 * time.Millisecond)
+	mustEnqueue(t, a, "b") // fills the queue
+
+	done := make(chan error, 1)
+	go func() { done <- a.enqueue(asyncMsg{priority: rfc5424.Info, msg: "c"}) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Block policy enqueue never unblocked once a slot freed up")
+	}
+
+	if got := a.Stats().Dropped; got != 0 {
+		t.Fatalf("Dropped = %d, want 0 under Block policy", got)
+	}
+}
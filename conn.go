@@ -0,0 +1,47 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"net"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// serverConn abstracts the wire format a Writer speaks to its syslog
+// daemon, the same split srslog and the stdlib log/syslog package make
+// internally. Local sockets get a bare BSD-style line per write; UDP,
+// TCP and TLS get msg.WriteTo's output, which carries the RFC 5425
+// octet-counted length prefix so a stream receiver can tell where one
+// message ends and the next begins.
+type serverConn interface {
+	writeMessage(msg *rfc5424.Message) (int, error)
+	setWriteDeadline(t time.Time) error
+	close() error
+}
+
+// rawConn writes msg.WriteTo's output (length prefix included) straight
+// to conn, one write per message, adding no framing of its own; this is
+// what goggler has always done for both UDP and plain TCP.
+type rawConn struct {
+	conn net.Conn
+}
+
+func newRawConn(conn net.Conn) *rawConn {
+	return &rawConn{conn: conn}
+}
+
+func (c *rawConn) writeMessage(msg *rfc5424.Message) (int, error) {
+	n, err := msg.WriteTo(c.conn)
+	return int(n), err
+}
+
+func (c *rawConn) setWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *rawConn) close() error {
+	return c.conn.Close()
+}
@@ -5,6 +5,7 @@ package goggler
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"net"
 	"os"
@@ -18,55 +19,71 @@ import (
 
 // A Writer is a connection to a syslog server.
 type Writer struct {
-	priority rfc5424.Priority
-	network  string
-	raddr    string
-	hostname string
-	appname  string
-	mu       sync.Mutex // guards conn
-	conn     net.Conn
+	priority  rfc5424.Priority
+	network   string
+	raddr     string
+	hostname  string
+	appname   string
+	local     bool        // true: probe a local socket (/dev/log etc.) instead of network/raddr
+	tlsConfig *tls.Config // non-nil: dial with TLS and RFC 5425 framing
+	opts      WriterOptions
+	messageID string                   // set via WithMessageID; attached to every message
+	defaultSD []rfc5424.StructuredData // set via WithDefaultSD; attached to every message
+	mu        sync.Mutex               // guards conn
+	conn      serverConn
 }
 
-// Dial establishes a connection to a log daemon by connecting to
-// address raddr on the specified network. Each write to the returned
-// writer sends a log message with the facility and severity
-// (from priority) and tag. If tag is empty, the os.Args[0] is used.
-// If network is empty, Dial will connect to the local syslog server.
-// Otherwise, see the documentation for net.Dial for valid values
-// of network and raddr.
-func Dial(network, raddr, appname string, p rfc5424.Priority) (*Writer, error) {
+// newWriter validates the common Dial/DialTLS/DialWithOptions arguments
+// and builds an unconnected Writer; callers still need to set any
+// transport-specific fields and call connect().
+func newWriter(network, raddr, appname string, p rfc5424.Priority) (*Writer, error) {
 	// check for valid priority
 	if p < 0 || p > rfc5424.Local7|rfc5424.Debug {
 		return nil, errors.New("log/syslog: invalid priority")
 	}
-	// if network is empty udp
-	if network == "" {
-		network = "udp"
-	}
 	// if appname is empty os.Args[0]
 	if appname == "" {
 		appname = os.Args[0]
 	}
-	// if appname is empty os.Args[0]
+	w := new(Writer)
+	w.priority = p
+	w.hostname, _ = os.Hostname()
+	w.appname = appname
+	w.opts = defaultWriterOptions()
+	// if network is empty, connect() will probe the local syslog socket
+	if network == "" {
+		w.local = true
+		return w, nil
+	}
 	if raddr == "" {
 		return nil, errors.New("syslog server address is needed")
 	}
-	// create a writer
-	w := new(Writer)
+	w.network = network
+	w.raddr = raddr
+	return w, nil
+}
+
+// Dial establishes a connection to a log daemon by connecting to
+// address raddr on the specified network. Each write to the returned
+// writer sends a log message with the facility and severity
+// (from priority) and tag. If tag is empty, the os.Args[0] is used.
+// If network is empty, Dial connects to the local syslog server
+// (/dev/log or one of its common aliases) instead of over the network.
+// Otherwise, see the documentation for net.Dial for valid values
+// of network and raddr.
+func Dial(network, raddr, appname string, p rfc5424.Priority) (*Writer, error) {
+	w, err := newWriter(network, raddr, appname, p)
+	if err != nil {
+		return nil, err
+	}
 	// locking
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	w.raddr = raddr
-	w.priority = p
-	w.hostname, _ = os.Hostname()
-	w.appname = appname
-	w.network = network
 	// connection
-	err := w.connect()
-	if err != nil {
+	if err := w.connect(); err != nil {
 		return nil, err
 	}
-	return w, err
+	return w, nil
 }
 
 // Close closes a connection to the syslog daemon.
@@ -75,20 +92,28 @@ func (w *Writer) Close() error {
 	defer w.mu.Unlock()
 
 	if w.conn != nil {
-		err := w.conn.Close()
+		err := w.conn.close()
 		w.conn = nil
 		return err
 	}
 	return nil
 }
 
-// Write sends a log message to the syslog daemon.
+// Write sends a log message to the syslog daemon, satisfying io.Writer.
+// The returned count is len(b) on success, not the number of bytes put
+// on the wire (which differs from len(b) once framing or structured
+// data is added), as io.Writer requires.
 func (w *Writer) Write(b []byte) (int, error) {
-	return w.writeAndRetry(w.priority, string(b))
+	if _, err := w.writeAndRetry(w.priority, string(b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
 }
 
-// write generates and writes a syslog formatted string.
-func (w *Writer) write(p rfc5424.Priority, msg string) (int, error) {
+// write generates and writes a syslog formatted string, attaching msgID
+// and sd (in addition to any default SD-ELEMENTs set via WithDefaultSD)
+// to the message. Callers must hold w.mu.
+func (w *Writer) write(p rfc5424.Priority, msgID, msg string, sd []rfc5424.StructuredData) (int, error) {
 	// bytes holder
 	var b []byte
 	// creates a syslog RFC5424 message
@@ -98,8 +123,8 @@ func (w *Writer) write(p rfc5424.Priority, msg string) (int, error) {
 	logMsg.Hostname = w.hostname
 	logMsg.AppName = w.appname
 	logMsg.ProcessID = strconv.Itoa(os.Getpid())
-	logMsg.MessageID = ""
-	logMsg.StructuredData = []rfc5424.StructuredData{}
+	logMsg.MessageID = msgID
+	logMsg.StructuredData = append(append([]rfc5424.StructuredData{}, w.defaultSD...), sd...)
 	logMsg.Message = []byte(msg)
 	// buffer
 	buf := bytes.NewBuffer(b)
@@ -107,37 +132,100 @@ func (w *Writer) write(p rfc5424.Priority, msg string) (int, error) {
 	logMsg.WriteTo(buf)
 	log.Infof("syslog message content: %s", buf.String())
 	// writer
-	res, err := logMsg.WriteTo(w.conn)
-	return int(res), err
+	if err := w.conn.setWriteDeadline(time.Now().Add(w.writeDeadline())); err != nil {
+		return 0, err
+	}
+	return w.conn.writeMessage(logMsg)
 }
 
-func (w *Writer) writeAndRetry(p rfc5424.Priority, s string) (int, error) {
+// writeAndRetry writes a message, reconnecting with exponential backoff
+// and jitter when the connection has gone bad. w.mu is only held for
+// each individual attempt, not across the sleeps between them: holding
+// it the whole time would block any other goroutine's Write/Info/etc.
+// on the same Writer for the full backoff window during an outage,
+// defeating the point of bounding a single write's latency.
+func (w *Writer) writeAndRetry(p rfc5424.Priority, s string, sd ...rfc5424.StructuredData) (int, error) {
 	pr := w.priority | p
+
+	n, err := w.writeLocked(pr, s, sd)
+	if err == nil {
+		return n, nil
+	}
+	lastErr := err
+
+	maxRetries := w.opts.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	backoff := w.opts.RetryBackoff
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		time.Sleep(jitter(backoff))
+		if backoff < w.opts.RetryBackoffMax {
+			backoff *= 2
+			if backoff > w.opts.RetryBackoffMax {
+				backoff = w.opts.RetryBackoffMax
+			}
+		}
+		n, err := w.reconnectAndWriteLocked(pr, s, sd)
+		if err == nil {
+			return n, nil
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// writeLocked attempts a single write against the current connection,
+// reading w.messageID under w.mu so it can't race with WithMessageID.
+func (w *Writer) writeLocked(p rfc5424.Priority, s string, sd []rfc5424.StructuredData) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if w.conn != nil {
-		if n, err := w.write(pr, s); err == nil {
-			return n, err
-		}
+	if w.conn == nil {
+		return 0, errors.New("goggler: not connected")
 	}
-	if err := w.connect(); err == nil {
+	return w.write(p, w.messageID, s, sd)
+}
+
+// reconnectAndWriteLocked reconnects and writes a single attempt,
+// holding w.mu only for that attempt.
+func (w *Writer) reconnectAndWriteLocked(p rfc5424.Priority, s string, sd []rfc5424.StructuredData) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.connect(); err != nil {
 		return 0, err
 	}
-	return w.write(pr, s)
+	return w.write(p, w.messageID, s, sd)
 }
 
 func (w *Writer) connect() (err error) {
-	var c net.Conn
 	if w.conn != nil {
 		// ignore err from close, it makes sense to continue anyway
-		w.conn.Close()
+		w.conn.close()
 		w.conn = nil
 	}
-	c, err = net.Dial(w.network, w.raddr)
-	if err == nil {
-		w.conn = c
+	if w.local {
+		c, network, dialErr := dialLocal()
+		if dialErr != nil {
+			return dialErr
+		}
+		w.network = network
+		w.conn = newBSDConn(c)
+		return nil
 	}
-	return
+	if w.tlsConfig != nil {
+		c, dialErr := tls.Dial(w.network, w.raddr, w.tlsConfig)
+		if dialErr != nil {
+			return dialErr
+		}
+		w.conn = newFramedConn(c)
+		return nil
+	}
+	c, dialErr := net.Dial(w.network, w.raddr)
+	if dialErr != nil {
+		return dialErr
+	}
+	w.conn = newRawConn(c)
+	return nil
 }
 
 // Emerg logs a message with severity LOG_EMERG
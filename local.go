@@ -0,0 +1,62 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// localSocketPaths lists the well-known local syslog socket paths, in
+// the order they're tried. This mirrors the stdlib's unexported
+// unixSyslog helper and what libc syslog(3) implementations probe.
+var localSocketPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// localSocketNetworks are tried against each path in order: most local
+// syslog daemons speak unixgram, but some (notably older syslog-ng
+// configs) only listen on a unix stream socket.
+var localSocketNetworks = []string{"unixgram", "unix"}
+
+// dialLocal tries each well-known local syslog socket in turn and
+// returns the first one that accepts a connection.
+func dialLocal() (conn net.Conn, network string, err error) {
+	for _, path := range localSocketPaths {
+		for _, network = range localSocketNetworks {
+			conn, err = net.Dial(network, path)
+			if err == nil {
+				return conn, network, nil
+			}
+		}
+	}
+	return nil, "", errors.New("goggler: no local syslog socket found")
+}
+
+// bsdConn writes messages using the traditional BSD syslog format (RFC
+// 3164) rather than RFC 5424, since most local daemons listening on
+// /dev/log don't understand structured messages.
+type bsdConn struct {
+	conn net.Conn
+}
+
+func newBSDConn(conn net.Conn) *bsdConn {
+	return &bsdConn{conn: conn}
+}
+
+func (c *bsdConn) writeMessage(msg *rfc5424.Message) (int, error) {
+	line := fmt.Sprintf("<%d>%s %s %s[%s]: %s",
+		msg.Priority, msg.Timestamp.Format(time.Stamp), msg.Hostname, msg.AppName, msg.ProcessID, msg.Message)
+	return c.conn.Write([]byte(line))
+}
+
+func (c *bsdConn) setWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *bsdConn) close() error {
+	return c.conn.Close()
+}
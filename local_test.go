@@ -0,0 +1,44 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+func TestBSDConnWriteMessageFormat(t *testing.T) {
+	buf := &bufConn{}
+	c := newBSDConn(buf)
+
+	ts := time.Date(2026, time.July, 27, 10, 30, 0, 0, time.UTC)
+	msg := &rfc5424.Message{
+		Priority:  rfc5424.Info,
+		Timestamp: ts,
+		Hostname:  "host",
+		AppName:   "app",
+		ProcessID: "42",
+		Message:   []byte("hello"),
+	}
+
+	if _, err := c.writeMessage(msg); err != nil {
+		t.Fatalf("writeMessage: %v", err)
+	}
+
+	expected := "<6>" + ts.Format(time.Stamp) + " host app[42]: hello"
+	if got := buf.String(); got != expected {
+		t.Fatalf("BSD line = %q, want %q", got, expected)
+	}
+}
+
+func TestDialLocalNoSocketAvailable(t *testing.T) {
+	// In the sandboxed test environment none of the well-known local
+	// syslog socket paths exist, so dialLocal should fail cleanly rather
+	// than hang or panic.
+	if _, _, err := dialLocal(); err == nil {
+		t.Fatal("dialLocal succeeded; expected an error when no local syslog socket is present")
+	}
+}
@@ -0,0 +1,105 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// WriterOptions configures write deadlines and reconnect behavior for a
+// Writer created with DialWithOptions.
+type WriterOptions struct {
+	// WriteTimeout bounds each write to the underlying connection, so a
+	// stalled syslog daemon can't block the caller indefinitely. Zero
+	// picks a sensible default: 20ms for a local socket, 50ms otherwise.
+	WriteTimeout time.Duration
+	// MaxRetries caps the number of reconnect-and-write attempts
+	// writeAndRetry makes after its first attempt fails, before giving
+	// up and returning the last error. Zero picks the default of 3 (via
+	// DialWithOptions; Dial and DialTLS always use the default). A
+	// negative value (e.g. -1) disables retries entirely, so only the
+	// initial attempt is made.
+	MaxRetries int
+	// RetryBackoff is the delay before the first reconnect attempt; it
+	// doubles on each subsequent attempt, capped at RetryBackoffMax, and
+	// is jittered to avoid every writer reconnecting in lockstep. Zero
+	// (or negative) picks the default of 50ms, as does a zero
+	// RetryBackoffMax (default 2s).
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+}
+
+// defaultWriterOptions are used by Dial and DialTLS, and by
+// DialWithOptions for any field left at its zero value.
+func defaultWriterOptions() WriterOptions {
+	return WriterOptions{
+		MaxRetries:      3,
+		RetryBackoff:    50 * time.Millisecond,
+		RetryBackoffMax: 2 * time.Second,
+	}
+}
+
+// DialWithOptions behaves like Dial but lets the caller tune write
+// deadlines and reconnect backoff via opts. Fields left at their zero
+// value fall back to defaultWriterOptions.
+func DialWithOptions(network, raddr, appname string, p rfc5424.Priority, opts WriterOptions) (*Writer, error) {
+	w, err := newWriter(network, raddr, appname, p)
+	if err != nil {
+		return nil, err
+	}
+	w.opts = mergeWriterOptions(opts)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// mergeWriterOptions fills any zero-valued field of opts from
+// defaultWriterOptions. MaxRetries is the one exception: it's only
+// replaced when exactly zero, since a negative MaxRetries is a
+// deliberate request to disable retries, not an unset field.
+func mergeWriterOptions(opts WriterOptions) WriterOptions {
+	def := defaultWriterOptions()
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = def.MaxRetries
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = def.RetryBackoff
+	}
+	if opts.RetryBackoffMax <= 0 {
+		opts.RetryBackoffMax = def.RetryBackoffMax
+	}
+	return opts
+}
+
+// writeDeadline returns how long a single write is allowed to take.
+func (w *Writer) writeDeadline() time.Duration {
+	if w.opts.WriteTimeout > 0 {
+		return w.opts.WriteTimeout
+	}
+	if w.local {
+		return 20 * time.Millisecond
+	}
+	return 50 * time.Millisecond
+}
+
+// jitter returns d plus or minus up to 25%, so that many writers
+// reconnecting to the same collector at once don't all retry in
+// lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	variance := int64(d) / 4
+	if variance == 0 {
+		return d
+	}
+	return d + time.Duration(rand.Int63n(2*variance+1)-variance)
+}
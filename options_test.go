@@ -0,0 +1,112 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+func TestJitterWithinBounds(t *testing.T) {
+	d := 100 * time.Millisecond
+	for i := 0; i < 1000; i++ {
+		got := jitter(d)
+		if got < 75*time.Millisecond || got > 125*time.Millisecond {
+			t.Fatalf("jitter(%s) = %s, want within +/-25%%", d, got)
+		}
+	}
+}
+
+func TestJitterZero(t *testing.T) {
+	if got := jitter(0); got != 0 {
+		t.Fatalf("jitter(0) = %s, want 0", got)
+	}
+}
+
+func TestMergeWriterOptionsFillsDefaults(t *testing.T) {
+	got := mergeWriterOptions(WriterOptions{})
+	want := defaultWriterOptions()
+	if got != want {
+		t.Fatalf("mergeWriterOptions(zero) = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeWriterOptionsKeepsOverrides(t *testing.T) {
+	opts := WriterOptions{MaxRetries: 7, RetryBackoff: time.Second, RetryBackoffMax: 5 * time.Second}
+	got := mergeWriterOptions(opts)
+	if got != opts {
+		t.Fatalf("mergeWriterOptions(%+v) = %+v, want unchanged", opts, got)
+	}
+}
+
+// TestMergeWriterOptionsKeepsExplicitNoRetry checks that an explicit
+// negative MaxRetries (the "disable retries" signal) survives merging,
+// unlike zero, which is indistinguishable from an unset field.
+func TestMergeWriterOptionsKeepsExplicitNoRetry(t *testing.T) {
+	got := mergeWriterOptions(WriterOptions{MaxRetries: -1})
+	if got.MaxRetries != -1 {
+		t.Fatalf("mergeWriterOptions(MaxRetries: -1).MaxRetries = %d, want -1", got.MaxRetries)
+	}
+}
+
+func TestWriteDeadlineDefaults(t *testing.T) {
+	local := &Writer{local: true}
+	if got := local.writeDeadline(); got != 20*time.Millisecond {
+		t.Fatalf("local writeDeadline = %s, want 20ms", got)
+	}
+
+	remote := &Writer{}
+	if got := remote.writeDeadline(); got != 50*time.Millisecond {
+		t.Fatalf("remote writeDeadline = %s, want 50ms", got)
+	}
+
+	withOverride := &Writer{opts: WriterOptions{WriteTimeout: 9 * time.Millisecond}}
+	if got := withOverride.writeDeadline(); got != 9*time.Millisecond {
+		t.Fatalf("overridden writeDeadline = %s, want 9ms", got)
+	}
+}
+
+// TestWriteAndRetryGivesUpAfterMaxRetries checks that writeAndRetry
+// stops reconnecting once it has exhausted MaxRetries, instead of
+// retrying forever against a server that's never coming back.
+func TestWriteAndRetryGivesUpAfterMaxRetries(t *testing.T) {
+	w, err := newWriter("tcp", "127.0.0.1:1", "app", rfc5424.Info)
+	if err != nil {
+		t.Fatalf("newWriter: %v", err)
+	}
+	w.opts = WriterOptions{
+		MaxRetries:      3,
+		RetryBackoff:    time.Millisecond,
+		RetryBackoffMax: 4 * time.Millisecond,
+	}
+
+	if _, err := w.writeAndRetry(w.priority, "hello"); err == nil {
+		t.Fatal("writeAndRetry succeeded against an address nothing listens on")
+	}
+}
+
+// TestWriteAndRetryNegativeMaxRetriesMakesOneAttempt checks that a
+// negative MaxRetries actually disables reconnect attempts, rather
+// than being silently bumped up to one retry.
+func TestWriteAndRetryNegativeMaxRetriesMakesOneAttempt(t *testing.T) {
+	w, err := newWriter("tcp", "127.0.0.1:1", "app", rfc5424.Info)
+	if err != nil {
+		t.Fatalf("newWriter: %v", err)
+	}
+	w.opts = WriterOptions{
+		MaxRetries:      -1,
+		RetryBackoff:    time.Second,
+		RetryBackoffMax: time.Second,
+	}
+
+	start := time.Now()
+	if _, err := w.writeAndRetry(w.priority, "hello"); err == nil {
+		t.Fatal("writeAndRetry succeeded against an address nothing listens on")
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("writeAndRetry took %s; a disabled retry should fail immediately without backing off", elapsed)
+	}
+}
@@ -0,0 +1,101 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// slogAttrsSDID is the SD-ID used for the SD-ELEMENT that carries slog
+// attributes. Callers who need a different SD-ID for their own data can
+// still use WithDefaultSD/*SD methods directly; NewSlogHandler only
+// owns this one element.
+const slogAttrsSDID = "slog@32473"
+
+// slogHandler adapts a Writer to slog.Handler, mapping slog levels to
+// RFC 5424 severities and slog attributes to SD-PARAMs.
+type slogHandler struct {
+	w     *Writer
+	opts  *slog.HandlerOptions
+	attrs []slog.Attr
+}
+
+// NewSlogHandler adapts w into a slog.Handler: slog.Record.Message
+// becomes the RFC 5424 MSG, the level is mapped to the closest severity,
+// and any attributes (from WithAttrs or the record itself) become
+// SD-PARAMs of a single SD-ELEMENT under slogAttrsSDID.
+func NewSlogHandler(w *Writer, opts *slog.HandlerOptions) slog.Handler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &slogHandler{w: w, opts: opts}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	var sd []rfc5424.StructuredData
+	if len(attrs) > 0 {
+		params := make([]rfc5424.SDParam, 0, len(attrs))
+		for _, a := range attrs {
+			params = append(params, rfc5424.SDParam{Name: a.Key, Value: a.Value.String()})
+		}
+		sd = append(sd, rfc5424.StructuredData{ID: slogAttrsSDID, Parameters: params})
+	}
+
+	_, err := h.w.writeAndRetry(slogSeverity(r.Level), r.Message, sd...)
+	return err
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	merged = append(merged, h.attrs...)
+	merged = append(merged, attrs...)
+	return &slogHandler{w: h.w, opts: h.opts, attrs: merged}
+}
+
+func (h *slogHandler) WithGroup(_ string) slog.Handler {
+	// RFC 5424 structured data doesn't nest the way slog groups do;
+	// grouped attributes still flatten into the single SD-ELEMENT above.
+	return h
+}
+
+// slogSeverity maps a slog.Level to the closest RFC 5424 severity,
+// following the level-to-verbosity convention slog itself documents
+// (Debug=-4, Info=0, Warn=4, Error=8, with room above Error for custom
+// levels).
+func slogSeverity(level slog.Level) rfc5424.Priority {
+	switch {
+	case level < slog.LevelInfo:
+		return rfc5424.Debug
+	case level < slog.LevelWarn:
+		return rfc5424.Info
+	case level < slog.LevelError:
+		return rfc5424.Warning
+	case level < slog.LevelError+4:
+		return rfc5424.Error
+	case level < slog.LevelError+8:
+		return rfc5424.Crit
+	case level < slog.LevelError+12:
+		return rfc5424.Alert
+	default:
+		return rfc5424.Emergency
+	}
+}
@@ -0,0 +1,73 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+func TestSlogSeverityMapping(t *testing.T) {
+	cases := []struct {
+		level slog.Level
+		want  rfc5424.Priority
+	}{
+		{slog.LevelDebug, rfc5424.Debug},
+		{slog.LevelInfo - 1, rfc5424.Debug},
+		{slog.LevelInfo, rfc5424.Info},
+		{slog.LevelWarn - 1, rfc5424.Info},
+		{slog.LevelWarn, rfc5424.Warning},
+		{slog.LevelError - 1, rfc5424.Warning},
+		{slog.LevelError, rfc5424.Error},
+		{slog.LevelError + 3, rfc5424.Error},
+		{slog.LevelError + 4, rfc5424.Crit},
+		{slog.LevelError + 7, rfc5424.Crit},
+		{slog.LevelError + 8, rfc5424.Alert},
+		{slog.LevelError + 11, rfc5424.Alert},
+		{slog.LevelError + 12, rfc5424.Emergency},
+		{slog.LevelError + 100, rfc5424.Emergency},
+	}
+	for _, c := range cases {
+		if got := slogSeverity(c.level); got != c.want {
+			t.Errorf("slogSeverity(%v) = %v, want %v", c.level, got, c.want)
+		}
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	h := NewSlogHandler(&Writer{}, nil)
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("default handler should not be enabled for Debug")
+	}
+	if !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("default handler should be enabled for Info")
+	}
+
+	withDebug := NewSlogHandler(&Writer{}, &slog.HandlerOptions{Level: slog.LevelDebug})
+	if !withDebug.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("handler configured with LevelDebug should be enabled for Debug")
+	}
+}
+
+func TestSlogHandlerHandleWritesMessage(t *testing.T) {
+	w := &Writer{
+		priority: rfc5424.Info,
+		hostname: "host",
+		appname:  "app",
+		opts:     defaultWriterOptions(),
+		conn:     stubConn{},
+	}
+	h := NewSlogHandler(w, nil)
+
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "disk usage high", 0)
+	r.AddAttrs(slog.String("mount", "/data"))
+
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+}
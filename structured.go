@@ -0,0 +1,76 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import "github.com/crewjam/rfc5424"
+
+// WithDefaultSD attaches sd to every message w writes from now on, in
+// addition to any SD-ELEMENTs passed to a specific *SD call. It returns
+// w so it can be chained onto a Dial call, e.g.
+//
+//	w, err := goggler.Dial("tcp", addr, "myapp", pri)
+//	w = w.WithDefaultSD(rfc5424.StructuredData{ID: "origin@32473", Parameters: ...})
+func (w *Writer) WithDefaultSD(sd ...rfc5424.StructuredData) *Writer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.defaultSD = sd
+	return w
+}
+
+// WithMessageID sets the RFC 5424 MSGID attached to every subsequent
+// message w writes. It returns w so it can be chained onto a Dial call.
+func (w *Writer) WithMessageID(id string) *Writer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messageID = id
+	return w
+}
+
+// EmergSD logs a message with severity LOG_EMERG, attaching sd to it.
+func (w *Writer) EmergSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Emergency, m, sd...)
+	return err
+}
+
+// AlertSD logs a message with severity LOG_ALERT, attaching sd to it.
+func (w *Writer) AlertSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Alert, m, sd...)
+	return err
+}
+
+// CritSD logs a message with severity LOG_CRIT, attaching sd to it.
+func (w *Writer) CritSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Crit, m, sd...)
+	return err
+}
+
+// ErrSD logs a message with severity LOG_ERR, attaching sd to it.
+func (w *Writer) ErrSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Error, m, sd...)
+	return err
+}
+
+// WarningSD logs a message with severity LOG_WARNING, attaching sd to it.
+func (w *Writer) WarningSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Warning, m, sd...)
+	return err
+}
+
+// NoticeSD logs a message with severity LOG_NOTICE, attaching sd to it.
+func (w *Writer) NoticeSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Notice, m, sd...)
+	return err
+}
+
+// InfoSD logs a message with severity LOG_INFO, attaching sd to it.
+func (w *Writer) InfoSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Info, m, sd...)
+	return err
+}
+
+// DebugSD logs a message with severity LOG_DEBUG, attaching sd to it.
+func (w *Writer) DebugSD(m string, sd ...rfc5424.StructuredData) error {
+	_, err := w.writeAndRetry(rfc5424.Debug, m, sd...)
+	return err
+}
@@ -0,0 +1,75 @@
+// Albert Espín 2020 MIT
+// +build !windows,!plan9
+
+package goggler
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/rfc5424"
+)
+
+// bufConn is a net.Conn backed by a bytes.Buffer, for asserting exactly
+// what a serverConn puts on the wire without a real socket.
+type bufConn struct {
+	bytes.Buffer
+}
+
+func (bufConn) Close() error                     { return nil }
+func (bufConn) LocalAddr() net.Addr              { return nil }
+func (bufConn) RemoteAddr() net.Addr             { return nil }
+func (bufConn) SetDeadline(time.Time) error      { return nil }
+func (bufConn) SetReadDeadline(time.Time) error  { return nil }
+func (bufConn) SetWriteDeadline(time.Time) error { return nil }
+
+// TestFramedConnWriteMessageIsNotDoubleFramed guards against wrapping a
+// second RFC 5425 length prefix around msg.WriteTo's output, which
+// already includes one: the frame body must be exactly the message
+// bytes, not "<len> <message bytes>" again.
+func TestFramedConnWriteMessageIsNotDoubleFramed(t *testing.T) {
+	buf := &bufConn{}
+	c := newFramedConn(buf)
+
+	msg := &rfc5424.Message{
+		Priority:  rfc5424.Info,
+		Timestamp: time.Now(),
+		Hostname:  "host",
+		AppName:   "app",
+		ProcessID: "1",
+		Message:   []byte("hello"),
+	}
+
+	if _, err := c.writeMessage(msg); err != nil {
+		t.Fatalf("writeMessage: %v", err)
+	}
+
+	body, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	want := strconv.Itoa(len(body)) + " " + string(body)
+
+	got := buf.String()
+	if got != want {
+		t.Fatalf("framed bytes = %q, want %q", got, want)
+	}
+
+	// The frame's length prefix must match the body that follows it, and
+	// that body must not itself start with another length prefix.
+	prefix, rest, ok := strings.Cut(got, " ")
+	if !ok {
+		t.Fatalf("frame %q has no length prefix", got)
+	}
+	n, err := strconv.Atoi(prefix)
+	if err != nil {
+		t.Fatalf("frame prefix %q is not a length: %v", prefix, err)
+	}
+	if n != len(rest) {
+		t.Fatalf("frame claims length %d but body is %d bytes: %q", n, len(rest), rest)
+	}
+}